@@ -10,6 +10,8 @@ import (
 	"github.com/goccy/go-graphviz"
 	"github.com/goccy/go-graphviz/cgraph"
 	"github.com/rs/zerolog/log"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
 
 	"github.com/florianloch/gomodgraph/internal/mods"
 )
@@ -18,20 +20,30 @@ type ModuleNode struct {
 	ModuleName   string
 	Version      string
 	GoModVersion string
-	Requires     []*DependencyVertex
-	RequiredBy   []*DependencyVertex
-	Highlight    bool
+	// Origin is the name of the backend (e.g. "gitlab" or "github") the module was fetched from.
+	Origin     string
+	Requires   []*DependencyVertex
+	RequiredBy []*DependencyVertex
+	Highlight  bool
+	// indirectPins records, for every module path this module's go.mod requires indirectly, the version it is
+	// pinned to. Since Go 1.17 this block is the full flattened transitive closure, so it is folded straight into
+	// transitiveMaxVersions rather than walked as graph edges (see BuildDependencyGraph).
+	indirectPins map[string]string
 }
 
-func (m *ModuleNode) addDependency(requires *ModuleNode, version string) {
+func (m *ModuleNode) addDependency(requires *ModuleNode, version string, isReplace bool, originalPath string) {
 	m.Requires = append(m.Requires, &DependencyVertex{
 		targetModule:  requires,
 		targetVersion: version,
+		isReplace:     isReplace,
+		originalPath:  originalPath,
 	})
 
 	requires.RequiredBy = append(requires.RequiredBy, &DependencyVertex{
 		targetModule:  m,
 		targetVersion: version,
+		isReplace:     isReplace,
+		originalPath:  originalPath,
 	})
 }
 
@@ -40,15 +52,26 @@ func (m *ModuleNode) clone() *ModuleNode {
 		ModuleName:   m.ModuleName,
 		Version:      m.Version,
 		GoModVersion: m.GoModVersion,
+		Origin:       m.Origin,
 		Requires:     m.Requires,
 		RequiredBy:   m.RequiredBy,
 		Highlight:    m.Highlight,
+		indirectPins: m.indirectPins,
 	}
 }
 
 type DependencyVertex struct {
 	targetModule  *ModuleNode
 	targetVersion string
+	// selectedVersion is the version of targetModule that Minimum Version Selection would actually pick for the
+	// consumer's build, i.e. the maximum version required across the consumer's full transitive require graph. It
+	// can differ from targetVersion when some other, deeper dependency requires a newer version of targetModule.
+	selectedVersion string
+	// isReplace indicates this edge exists because of a `replace` directive rewriting originalPath to targetModule.
+	isReplace bool
+	// originalPath is the module path as written in the `require` directive, before `replace` rewrote it. Only set
+	// when isReplace is true.
+	originalPath string
 }
 
 type DependencyGraph struct {
@@ -106,6 +129,7 @@ func BuildDependencyGraph(modFiles []*mods.Module) *DependencyGraph {
 			ModuleName:   moduleName,
 			GoModVersion: goVersion,
 			Version:      module.Version,
+			Origin:       module.Origin,
 		}
 
 		modulesMap[moduleName] = moduleNode
@@ -122,72 +146,304 @@ func BuildDependencyGraph(modFiles []*mods.Module) *DependencyGraph {
 
 		moduleNode := modulesMap[modFile.Module.Mod.Path]
 
-		// TODO: Handle `Replace` directive
-
 		for _, requiredModule := range modFile.Require {
+			requiredPath := requiredModule.Mod.Path
+			requiredVersion := requiredModule.Mod.Version
+
+			targetPath, targetVersion, isReplace := resolveReplace(modFile, requiredPath, requiredVersion)
+
+			if isReplace && modfile.IsDirectoryPath(targetPath) {
+				log.Info().Msgf("%q replaces %q with local path %q, cannot be resolved to a graph node",
+					moduleNode.ModuleName, requiredPath, targetPath)
+
+				continue
+			}
+
 			if requiredModule.Indirect {
+				// Since Go 1.17, the indirect block is the full flattened transitive closure, not just this
+				// module's own indirect dependencies. Drawing an edge for every one of them would make the overview
+				// graph massively over-connected and no longer reflect direct dependencies, so we don't add a
+				// DependencyVertex here. MVS still needs this information, though: a version pinned only via an
+				// indirect require can be higher than anything seen on the direct-edge graph, so we record it as an
+				// indirectPin and fold it into transitiveMaxVersions instead.
+				if moduleNode.indirectPins == nil {
+					moduleNode.indirectPins = make(map[string]string)
+				}
+
+				if current, ok := moduleNode.indirectPins[targetPath]; !ok || semver.Compare(targetVersion, current) > 0 {
+					moduleNode.indirectPins[targetPath] = targetVersion
+				}
+
 				continue
 			}
 
-			requiredModuleNode, ok := modulesMap[requiredModule.Mod.Path]
+			requiredModuleNode, ok := modulesMap[targetPath]
 			if !ok {
 				// Not in our set of considered dependencies
 				continue
 			}
 
-			moduleNode.addDependency(requiredModuleNode, requiredModule.Mod.Version)
+			originalPath := ""
+			if isReplace {
+				originalPath = requiredPath
+			}
+
+			moduleNode.addDependency(requiredModuleNode, targetVersion, isReplace, originalPath)
 		}
 	}
 
+	resolveSelectedVersions(modulesMap)
+
 	return NewDependencyGraph(modulesMap, false)
 }
 
+// resolveReplace rewrites requiredPath/requiredVersion by the applicable `replace` directive in modFile, if any. A
+// replace pinned to a specific version of the old path only applies when requiredVersion matches it; an unversioned
+// replace applies regardless of the required version, mirroring the go command's own precedence rules. It returns
+// the (possibly unchanged) path/version and whether a replace applied.
+func resolveReplace(modFile *modfile.File, requiredPath, requiredVersion string) (string, string, bool) {
+	var pathOnlyMatch, versionedMatch *modfile.Replace
+
+	for _, r := range modFile.Replace {
+		if r.Old.Path != requiredPath {
+			continue
+		}
+
+		if r.Old.Version == "" {
+			pathOnlyMatch = r
+
+			continue
+		}
+
+		if r.Old.Version == requiredVersion {
+			versionedMatch = r
+		}
+	}
+
+	replace := versionedMatch
+	if replace == nil {
+		replace = pathOnlyMatch
+	}
+
+	if replace == nil {
+		return requiredPath, requiredVersion, false
+	}
+
+	return replace.New.Path, replace.New.Version, true
+}
+
+// resolveSelectedVersions performs Minimum Version Selection across the graph: for every module, and every module
+// path it transitively requires, it determines the maximum version requested anywhere in its dependency tree and
+// records it as selectedVersion on the corresponding direct DependencyVertex.
+func resolveSelectedVersions(modulesMap map[string]*ModuleNode) {
+	for _, moduleNode := range modulesMap {
+		maxVersions := transitiveMaxVersions(moduleNode)
+
+		for _, dependency := range moduleNode.Requires {
+			if selected, ok := maxVersions[dependency.targetModule.ModuleName]; ok {
+				dependency.selectedVersion = selected
+			}
+		}
+	}
+}
+
+// transitiveMaxVersions walks root's full require graph and, for every module path reached, returns the maximum
+// semver required anywhere below root. This folds in each visited node's indirectPins as well as its direct
+// Requires: an indirect require can pin a higher version than any direct edge does, and since its block is already
+// the fully flattened transitive closure, a pinned path is never recursed into further. Cycles (which should not
+// occur in a well-formed module graph, but `replace` directives can in principle introduce them) are broken by only
+// visiting each node once.
+func transitiveMaxVersions(root *ModuleNode) map[string]string {
+	maxVersions := make(map[string]string)
+	visited := make(map[*ModuleNode]bool)
+
+	considerVersion := func(path, version string) {
+		if current, ok := maxVersions[path]; !ok || semver.Compare(version, current) > 0 {
+			maxVersions[path] = version
+		}
+	}
+
+	var walk func(node *ModuleNode)
+
+	walk = func(node *ModuleNode) {
+		if visited[node] {
+			return
+		}
+
+		visited[node] = true
+
+		for _, dependency := range node.Requires {
+			considerVersion(dependency.targetModule.ModuleName, dependency.targetVersion)
+
+			walk(dependency.targetModule)
+		}
+
+		for path, version := range node.indirectPins {
+			considerVersion(path, version)
+		}
+	}
+
+	walk(root)
+
+	return maxVersions
+}
+
 func (d *DependencyGraph) LookupNode(moduleName string) *ModuleNode {
 	return d.modulesMap[moduleName]
 }
 
+// SubgraphFrom returns the single-hop neighborhood of centerNode, i.e. its direct dependencies and dependents. It is
+// equivalent to SubgraphFromWithDepth(centerNode, 1).
 func (d *DependencyGraph) SubgraphFrom(centerNode *ModuleNode) *DependencyGraph {
+	return d.SubgraphFromWithDepth(centerNode, 1)
+}
+
+// SubgraphFromWithDepth returns a subgraph containing centerNode plus every module reachable from it within depth
+// hops, following both Requires and RequiredBy edges (i.e. both dependencies and dependents count towards depth). A
+// non-positive depth falls back to 1.
+func (d *DependencyGraph) SubgraphFromWithDepth(centerNode *ModuleNode, depth int) *DependencyGraph {
 	if d.isSubgraph {
 		panic("Deriving a subgraph from a subgraph is not recommended")
 	}
 
-	subgraphNodesMap := make(map[string]*ModuleNode)
+	if depth <= 0 {
+		depth = 1
+	}
 
-	copiedNode := *centerNode
-	centerNode = &copiedNode
-	centerNode.Highlight = true
+	included := bfsWithinDepth(centerNode, depth)
 
-	subgraphNodesMap[centerNode.ModuleName] = centerNode
+	clones := make(map[*ModuleNode]*ModuleNode, len(included))
+	subgraphNodesMap := make(map[string]*ModuleNode, len(included))
 
-	// We copy every required module and prune its dependencies
-	for _, dependency := range centerNode.Requires {
-		cloneModule := dependency.targetModule.clone()
-		cloneModule.Requires = nil
+	for node := range included {
+		clone := node.clone()
+		subgraphNodesMap[clone.ModuleName] = clone
+		clones[node] = clone
+	}
 
-		subgraphNodesMap[dependency.targetModule.ModuleName] = cloneModule
+	clones[centerNode].Highlight = true
 
-		// We also fix this reference because otherwise subgraphNodesMap would not be complete, i.e. there would be
-		// references to nodes not contained in the map. The reference would point to a node not contained in the map
-		// instead of pointing to the node in the map representing the same module.
-		// The rendering implementation depends on the map being complete.
-		dependency.targetModule = cloneModule
+	// Rewire every clone's edges to point at sibling clones, dropping edges that leave the included set, so the
+	// subgraph is self-contained and the rendering implementation never sees a node missing from the map.
+	for node, clone := range clones {
+		clone.Requires = rewireEdges(node.Requires, clones, included)
+		clone.RequiredBy = rewireEdges(node.RequiredBy, clones, included)
 	}
 
-	// We copy every module requiring the given center module and prune all dependencies, except the one to the center node
-	for _, dependency := range centerNode.RequiredBy {
-		clonedModule := dependency.targetModule.clone()
-		clonedModule.Requires = []*DependencyVertex{{
-			targetModule:  centerNode,
-			targetVersion: dependency.targetVersion,
-		}}
+	return NewDependencyGraph(subgraphNodesMap, true)
+}
 
-		subgraphNodesMap[dependency.targetModule.ModuleName] = clonedModule
+// bfsWithinDepth returns every ModuleNode reachable from root within depth hops, following both Requires and
+// RequiredBy edges, including root itself.
+func bfsWithinDepth(root *ModuleNode, depth int) map[*ModuleNode]bool {
+	distances := map[*ModuleNode]int{root: 0}
+	queue := []*ModuleNode{root}
+
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+
+		if distances[node] >= depth {
+			continue
+		}
+
+		neighbors := make([]*ModuleNode, 0, len(node.Requires)+len(node.RequiredBy))
+		for _, dependency := range node.Requires {
+			neighbors = append(neighbors, dependency.targetModule)
+		}
+
+		for _, dependency := range node.RequiredBy {
+			neighbors = append(neighbors, dependency.targetModule)
+		}
+
+		for _, neighbor := range neighbors {
+			if _, seen := distances[neighbor]; seen {
+				continue
+			}
+
+			distances[neighbor] = distances[node] + 1
+			queue = append(queue, neighbor)
+		}
 	}
 
-	return NewDependencyGraph(subgraphNodesMap, true)
+	included := make(map[*ModuleNode]bool, len(distances))
+	for node := range distances {
+		included[node] = true
+	}
+
+	return included
+}
+
+// rewireEdges clones edges whose target is in included, pointing each clone at its counterpart in clones instead of
+// at the original (now-excluded-from-the-subgraph) node.
+func rewireEdges(edges []*DependencyVertex, clones map[*ModuleNode]*ModuleNode, included map[*ModuleNode]bool) []*DependencyVertex {
+	var rewired []*DependencyVertex
+
+	for _, dependency := range edges {
+		if !included[dependency.targetModule] {
+			continue
+		}
+
+		rewired = append(rewired, &DependencyVertex{
+			targetModule:    clones[dependency.targetModule],
+			targetVersion:   dependency.targetVersion,
+			selectedVersion: dependency.selectedVersion,
+			isReplace:       dependency.isReplace,
+			originalPath:    dependency.originalPath,
+		})
+	}
+
+	return rewired
+}
+
+// originFillColors is a small, deterministic palette cycled over the distinct origins (backend names) encountered
+// in a graph, so modules fetched from different ModFileBackend instances remain visually distinguishable.
+var originFillColors = []string{"floralwhite", "lightcyan", "honeydew", "lavenderblush", "cornsilk"}
+
+func (d *DependencyGraph) fillColorFor(origin string, originColors map[string]string) string {
+	if origin == "" {
+		return "floralwhite"
+	}
+
+	if color, ok := originColors[origin]; ok {
+		return color
+	}
+
+	color := originFillColors[len(originColors)%len(originFillColors)]
+	originColors[origin] = color
+
+	return color
+}
+
+// edgeColorFor picks an edge color reflecting how the required version compares to what MVS would actually select
+// and to the target module's own tagged version:
+//   - dimgrey: neither of the below applies, e.g. targetVersion, selectedVersion and the target module's tagged
+//     Version all agree - up to date. Edges whose versions are missing also fall back to dimgrey.
+//   - darkorange: a deeper dependency elsewhere in the graph requires a newer version than this edge does, so MVS
+//     will select that newer version for this consumer's build regardless of what this edge states.
+//   - firebrick: nothing forces an upgrade, but the target module has since been tagged with a newer Version than
+//     what's required here - the consumer is falling behind the latest release.
+func edgeColorFor(dependency *DependencyVertex) string {
+	targetVersion := dependency.targetVersion
+	selectedVersion := dependency.selectedVersion
+
+	if selectedVersion != "" && targetVersion != "" && semver.Compare(selectedVersion, targetVersion) > 0 {
+		return "darkorange"
+	}
+
+	moduleVersion := dependency.targetModule.Version
+
+	if moduleVersion != "" && targetVersion != "" && semver.Compare(moduleVersion, targetVersion) > 0 {
+		return "firebrick"
+	}
+
+	return "dimgrey"
 }
 
-func (d *DependencyGraph) Render(writer io.Writer, goRegistryPrefix string, format graphviz.Format) error {
+// Render renders the graph via Graphviz. registryPrefixes maps an origin (backend name, see ModuleNode.Origin) to
+// the registry prefix that should be trimmed from module names fetched from that origin, allowing the prefix to
+// differ per backend (e.g. a GitLab host vs. "github.com").
+func (d *DependencyGraph) Render(writer io.Writer, registryPrefixes map[string]string, format graphviz.Format) error {
 	g := graphviz.New()
 
 	graph, err := g.Graph()
@@ -201,6 +457,7 @@ func (d *DependencyGraph) Render(writer io.Writer, goRegistryPrefix string, form
 
 	// First, create a lookup map containing all nodes as Graphviz nodes
 	graphNodes := make(map[*ModuleNode]*cgraph.Node)
+	originColors := make(map[string]string)
 
 	for _, moduleNode := range d.modulesList {
 		n, err := graph.CreateNode(moduleNode.ModuleName)
@@ -214,7 +471,7 @@ func (d *DependencyGraph) Render(writer io.Writer, goRegistryPrefix string, form
 			version = "<no version yet>"
 		}
 
-		n.SetLabel(fmt.Sprintf("%s\n%s (go%s)", strings.TrimPrefix(moduleNode.ModuleName, goRegistryPrefix), version, moduleNode.GoModVersion))
+		n.SetLabel(fmt.Sprintf("%s\n%s (go%s)", strings.TrimPrefix(moduleNode.ModuleName, registryPrefixes[moduleNode.Origin]), version, moduleNode.GoModVersion))
 
 		// We need to fill the node in order to make the whole box a link
 		n.SetStyle(cgraph.FilledNodeStyle)
@@ -226,7 +483,7 @@ func (d *DependencyGraph) Render(writer io.Writer, goRegistryPrefix string, form
 		} else {
 			n.SetURL(fmt.Sprintf("/?mod=%s", url.QueryEscape(moduleNode.ModuleName)))
 			n.SetShape(cgraph.BoxShape)
-			n.SetFillColor("floralwhite")
+			n.SetFillColor(d.fillColorFor(moduleNode.Origin, originColors))
 		}
 
 		graphNodes[moduleNode] = n
@@ -245,17 +502,15 @@ func (d *DependencyGraph) Render(writer io.Writer, goRegistryPrefix string, form
 				return fmt.Errorf("creating Graphviz edge: %w", err)
 			}
 
-			color := "dimgrey"
-
-			// In case the required version is not equal the latest version color the edge differently
-			if dependency.targetVersion != dependency.targetModule.Version && dependency.targetModule.Version != "" {
-				color = "darkorange"
-			}
-
 			e.SetLabel(dependency.targetVersion)
-			e.SetColor(color)
-			e.SetFontColor(color)
+			e.SetColor(edgeColorFor(dependency))
+			e.SetFontColor(edgeColorFor(dependency))
 			e.SetArrowSize(0.5)
+
+			if dependency.isReplace {
+				e.SetStyle(cgraph.DashedEdgeStyle)
+				e.SetTooltip(fmt.Sprintf("replaces %s", dependency.originalPath))
+			}
 		}
 	}
 
@@ -266,3 +521,87 @@ func (d *DependencyGraph) Render(writer io.Writer, goRegistryPrefix string, form
 
 	return nil
 }
+
+// RenderReleaseWaves renders only the modules that ReleasePlan flags as release candidates, clustered by wave so
+// the order in which they should be re-tagged is visible at a glance.
+func (d *DependencyGraph) RenderReleaseWaves(writer io.Writer, registryPrefixes map[string]string, format graphviz.Format) error {
+	candidates, _ := ReleasePlan(d)
+
+	g := graphviz.New()
+
+	graph, err := g.Graph()
+	if err != nil {
+		return fmt.Errorf("instancing Graphviz graph: %w", err)
+	}
+
+	graph.SetConcentrate(true)
+	graph.SetCenter(true)
+
+	byWave := make(map[int][]ReleaseCandidate)
+
+	for _, candidate := range candidates {
+		byWave[candidate.Wave] = append(byWave[candidate.Wave], candidate)
+	}
+
+	waves := make([]int, 0, len(byWave))
+	for wave := range byWave {
+		waves = append(waves, wave)
+	}
+
+	sort.Ints(waves)
+
+	graphNodes := make(map[string]*cgraph.Node, len(candidates))
+
+	for _, wave := range waves {
+		cluster, err := graph.SubGraph(fmt.Sprintf("cluster_wave_%d", wave), 1)
+		if err != nil {
+			return fmt.Errorf("creating cluster for release wave %d: %w", wave, err)
+		}
+
+		cluster.SetLabel(fmt.Sprintf("wave %d", wave))
+
+		for _, candidate := range byWave[wave] {
+			node, err := cluster.CreateNode(candidate.ModuleName)
+			if err != nil {
+				return fmt.Errorf("creating Graphviz node: %w", err)
+			}
+
+			moduleNode := d.modulesMap[candidate.ModuleName]
+
+			node.SetLabel(fmt.Sprintf("%s\n%s -> %s",
+				strings.TrimPrefix(candidate.ModuleName, registryPrefixes[moduleNode.Origin]),
+				candidate.CurrentVersion,
+				candidate.SuggestedVersion))
+			node.SetStyle(cgraph.FilledNodeStyle)
+			node.SetShape(cgraph.BoxShape)
+			node.SetFillColor("goldenrod1")
+
+			graphNodes[candidate.ModuleName] = node
+		}
+	}
+
+	for _, candidate := range candidates {
+		for _, bump := range candidate.Bumps {
+			targetNode, ok := graphNodes[bump.DependencyModule]
+			if !ok {
+				continue
+			}
+
+			id := fmt.Sprintf("%s:%s:%s", candidate.ModuleName, bump.DependencyModule, bump.Level)
+
+			e, err := graph.CreateEdge(id, graphNodes[candidate.ModuleName], targetNode)
+			if err != nil {
+				return fmt.Errorf("creating Graphviz edge: %w", err)
+			}
+
+			e.SetLabel(bump.Level.String())
+			e.SetArrowSize(0.5)
+		}
+	}
+
+	if err := g.Render(graph, format, writer); err != nil {
+		return fmt.Errorf("rendering release wave graph: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,25 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_resolveSelectedVersions_foldsInIndirectPins(t *testing.T) {
+	leaf := &ModuleNode{ModuleName: "example.com/leaf", Version: "v1.0.0"}
+	consumer := &ModuleNode{ModuleName: "example.com/consumer", Version: "v1.0.0"}
+
+	consumer.addDependency(leaf, "v1.0.0", false, "")
+	// A deeper dependency, never drawn as a graph edge, pins leaf to a higher version via the indirect block.
+	consumer.indirectPins = map[string]string{leaf.ModuleName: "v1.2.0"}
+
+	modulesMap := map[string]*ModuleNode{
+		leaf.ModuleName:     leaf,
+		consumer.ModuleName: consumer,
+	}
+
+	resolveSelectedVersions(modulesMap)
+
+	require.Equal(t, "v1.2.0", consumer.Requires[0].selectedVersion)
+}
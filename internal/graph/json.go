@@ -0,0 +1,60 @@
+package graph
+
+// NodeJSON is the JSON representation of a ModuleNode, as served by GraphRenderService's /api/graph endpoints.
+type NodeJSON struct {
+	Name         string `json:"name"`
+	Version      string `json:"version"`
+	GoModVersion string `json:"goModVersion"`
+	Origin       string `json:"origin"`
+	Highlight    bool   `json:"highlight"`
+}
+
+// EdgeJSON is the JSON representation of a DependencyVertex.
+type EdgeJSON struct {
+	From            string `json:"from"`
+	To              string `json:"to"`
+	RequiredVersion string `json:"requiredVersion"`
+	// SelectedVersion is the version Minimum Version Selection would actually pick, see DependencyVertex.selectedVersion.
+	SelectedVersion string `json:"selectedVersion,omitempty"`
+	IsReplace       bool   `json:"isReplace"`
+	// OriginalPath is the module path as written in the `require` directive, before a `replace` directive rewrote
+	// it. Only set when IsReplace is true.
+	OriginalPath string `json:"originalPath,omitempty"`
+}
+
+// GraphJSON is the JSON representation of a DependencyGraph (or a subgraph of one).
+type GraphJSON struct {
+	Nodes []NodeJSON `json:"nodes"`
+	Edges []EdgeJSON `json:"edges"`
+}
+
+// ToJSON renders d as a GraphJSON, suitable for encoding/json. Nodes and edges are emitted in the same deterministic
+// order d.modulesList already maintains.
+func (d *DependencyGraph) ToJSON() GraphJSON {
+	nodes := make([]NodeJSON, 0, len(d.modulesList))
+
+	var edges []EdgeJSON
+
+	for _, node := range d.modulesList {
+		nodes = append(nodes, NodeJSON{
+			Name:         node.ModuleName,
+			Version:      node.Version,
+			GoModVersion: node.GoModVersion,
+			Origin:       node.Origin,
+			Highlight:    node.Highlight,
+		})
+
+		for _, dependency := range node.Requires {
+			edges = append(edges, EdgeJSON{
+				From:            node.ModuleName,
+				To:              dependency.targetModule.ModuleName,
+				RequiredVersion: dependency.targetVersion,
+				SelectedVersion: dependency.selectedVersion,
+				IsReplace:       dependency.isReplace,
+				OriginalPath:    dependency.originalPath,
+			})
+		}
+	}
+
+	return GraphJSON{Nodes: nodes, Edges: edges}
+}
@@ -0,0 +1,61 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ToJSON(t *testing.T) {
+	leaf := &ModuleNode{ModuleName: "example.com/leaf", Version: "v1.1.0", Origin: "gitlab"}
+	consumer := &ModuleNode{ModuleName: "example.com/consumer", Version: "v1.0.0", Origin: "gitlab"}
+
+	consumer.addDependency(leaf, "v1.0.0", false, "")
+
+	d := NewDependencyGraph(map[string]*ModuleNode{
+		leaf.ModuleName:     leaf,
+		consumer.ModuleName: consumer,
+	}, false)
+
+	result := d.ToJSON()
+
+	require.Len(t, result.Nodes, 2)
+	require.Len(t, result.Edges, 1)
+	require.Equal(t, consumer.ModuleName, result.Edges[0].From)
+	require.Equal(t, leaf.ModuleName, result.Edges[0].To)
+	require.Equal(t, "v1.0.0", result.Edges[0].RequiredVersion)
+	require.False(t, result.Edges[0].IsReplace)
+}
+
+func Test_SubgraphFromWithDepth(t *testing.T) {
+	a := &ModuleNode{ModuleName: "example.com/a", Version: "v1.0.0"}
+	b := &ModuleNode{ModuleName: "example.com/b", Version: "v1.0.0"}
+	c := &ModuleNode{ModuleName: "example.com/c", Version: "v1.0.0"}
+
+	a.addDependency(b, "v1.0.0", false, "")
+	b.addDependency(c, "v1.0.0", false, "")
+
+	d := NewDependencyGraph(map[string]*ModuleNode{
+		a.ModuleName: a,
+		b.ModuleName: b,
+		c.ModuleName: c,
+	}, false)
+
+	depthOne := d.SubgraphFromWithDepth(a, 1)
+	require.NotNil(t, depthOne.LookupNode(a.ModuleName))
+	require.NotNil(t, depthOne.LookupNode(b.ModuleName))
+	require.Nil(t, depthOne.LookupNode(c.ModuleName))
+
+	depthTwo := d.SubgraphFromWithDepth(a, 2)
+	require.NotNil(t, depthTwo.LookupNode(a.ModuleName))
+	require.NotNil(t, depthTwo.LookupNode(b.ModuleName))
+	require.NotNil(t, depthTwo.LookupNode(c.ModuleName))
+
+	centerNode := depthTwo.LookupNode(a.ModuleName)
+	require.True(t, centerNode.Highlight)
+	require.Len(t, centerNode.Requires, 1)
+
+	bNode := depthTwo.LookupNode(b.ModuleName)
+	require.Len(t, bNode.Requires, 1)
+	require.Equal(t, c.ModuleName, bNode.Requires[0].targetModule.ModuleName)
+}
@@ -0,0 +1,395 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// BumpLevel classifies how significant a version bump is, ordered so that the largest applicable level wins when
+// aggregating several bumps (e.g. a module picking up both a patch and a minor dependency bump needs a minor
+// release of its own).
+type BumpLevel int
+
+const (
+	BumpNone BumpLevel = iota
+	BumpPatch
+	BumpMinor
+	BumpMajor
+)
+
+func (b BumpLevel) String() string {
+	switch b {
+	case BumpPatch:
+		return "patch"
+	case BumpMinor:
+		return "minor"
+	case BumpMajor:
+		return "major"
+	default:
+		return "none"
+	}
+}
+
+// MarshalJSON renders a BumpLevel as its string form (e.g. "minor") rather than the underlying int, so the /releases
+// JSON API is self-describing.
+func (b BumpLevel) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + b.String() + `"`), nil
+}
+
+// DependencyBump describes a single outdated dependency that a ReleaseCandidate would pick up by re-tagging.
+type DependencyBump struct {
+	DependencyModule string
+	RequiredVersion  string
+	LatestVersion    string
+	Level            BumpLevel
+}
+
+// ReleaseCandidate is a module whose currently tagged Version lags behind at least one of its dependencies' latest
+// tagged Version, i.e. re-tagging it would pick up real changes.
+type ReleaseCandidate struct {
+	ModuleName       string
+	CurrentVersion   string
+	SuggestedVersion string
+	Bumps            []DependencyBump
+	// Downstream lists modules requiring this one, which will in turn need their own re-tag once this module is
+	// released, as their pinned version of it would then be outdated too.
+	Downstream []string
+	// Wave is this module's position in the release order: wave 0 are leaves with no outdated dependencies of
+	// their own, wave N depends only on modules in waves < N.
+	Wave int
+}
+
+// Cycle is a set of modules whose require graph forms a cycle, reported separately because Minimum Version
+// Selection - and therefore a release wave ordering - is not well-defined across a cycle.
+type Cycle struct {
+	Modules []string
+}
+
+// ReleasePlan walks d in reverse topological order (dependencies before their consumers) and returns every module
+// that needs a new tag to pick up a dependency's release, grouped into waves. Cycles in the require graph are
+// broken at their lowest-version edge so the rest of the plan can still be computed, and reported separately.
+func ReleasePlan(d *DependencyGraph) ([]ReleaseCandidate, []Cycle) {
+	pending := make(map[*ModuleNode]int, len(d.modulesList))
+	for _, node := range d.modulesList {
+		pending[node] = len(node.Requires)
+	}
+
+	broken := make(map[*DependencyVertex]bool)
+	processed := make(map[*ModuleNode]bool, len(d.modulesList))
+	wave := make(map[*ModuleNode]int, len(d.modulesList))
+
+	queue := nodesWithNoPending(d.modulesList, pending)
+
+	var (
+		order  []*ModuleNode
+		cycles []Cycle
+	)
+
+	for len(processed) < len(d.modulesList) {
+		for len(queue) > 0 {
+			node := queue[0]
+			queue = queue[1:]
+
+			if processed[node] {
+				continue
+			}
+
+			processed[node] = true
+			order = append(order, node)
+			wave[node] = waveFor(node, wave)
+
+			for _, dependant := range node.RequiredBy {
+				consumer := dependant.targetModule
+
+				if processed[consumer] {
+					continue
+				}
+
+				pending[consumer]--
+
+				if pending[consumer] == 0 {
+					queue = append(queue, consumer)
+				}
+			}
+		}
+
+		if len(processed) == len(d.modulesList) {
+			break
+		}
+
+		var remaining []*ModuleNode
+
+		for _, node := range d.modulesList {
+			if !processed[node] {
+				remaining = append(remaining, node)
+			}
+		}
+
+		cycle := findCycle(remaining, broken)
+		if cycle == nil {
+			// Defensive: should not happen for a well-formed graph, avoid looping forever.
+			break
+		}
+
+		cycles = append(cycles, Cycle{Modules: moduleNames(cycle)})
+
+		from, dep := weakestEdge(cycle)
+		broken[dep] = true
+		pending[from]--
+
+		if pending[from] == 0 {
+			queue = append(queue, from)
+		}
+	}
+
+	candidates := make([]ReleaseCandidate, 0, len(order))
+
+	for _, node := range order {
+		bumps := outdatedBumps(node)
+		if len(bumps) == 0 {
+			continue
+		}
+
+		overall := BumpPatch
+		for _, b := range bumps {
+			if b.Level > overall {
+				overall = b.Level
+			}
+		}
+
+		var downstream []string
+
+		for _, rb := range node.RequiredBy {
+			downstream = append(downstream, rb.targetModule.ModuleName)
+		}
+
+		sort.Strings(downstream)
+
+		candidates = append(candidates, ReleaseCandidate{
+			ModuleName:       node.ModuleName,
+			CurrentVersion:   node.Version,
+			SuggestedVersion: bumpVersion(node.Version, overall),
+			Bumps:            bumps,
+			Downstream:       downstream,
+			Wave:             wave[node],
+		})
+	}
+
+	return candidates, cycles
+}
+
+func nodesWithNoPending(nodes []*ModuleNode, pending map[*ModuleNode]int) []*ModuleNode {
+	var queue []*ModuleNode
+
+	for _, node := range nodes {
+		if pending[node] == 0 {
+			queue = append(queue, node)
+		}
+	}
+
+	return queue
+}
+
+func waveFor(node *ModuleNode, wave map[*ModuleNode]int) int {
+	w := 0
+
+	for _, dep := range node.Requires {
+		if dw, ok := wave[dep.targetModule]; ok && dw+1 > w {
+			w = dw + 1
+		}
+	}
+
+	return w
+}
+
+func outdatedBumps(node *ModuleNode) []DependencyBump {
+	var bumps []DependencyBump
+
+	for _, dep := range node.Requires {
+		required := dep.targetVersion
+		latest := dep.targetModule.Version
+
+		if required == "" || latest == "" || semver.Compare(latest, required) <= 0 {
+			continue
+		}
+
+		bumps = append(bumps, DependencyBump{
+			DependencyModule: dep.targetModule.ModuleName,
+			RequiredVersion:  required,
+			LatestVersion:    latest,
+			Level:            bumpLevelBetween(required, latest),
+		})
+	}
+
+	return bumps
+}
+
+func bumpLevelBetween(required, latest string) BumpLevel {
+	if semver.Major(required) != semver.Major(latest) {
+		return BumpMajor
+	}
+
+	if semver.MajorMinor(required) != semver.MajorMinor(latest) {
+		return BumpMinor
+	}
+
+	return BumpPatch
+}
+
+func bumpVersion(version string, level BumpLevel) string {
+	major, minor, patch, ok := parseSemver(version)
+	if !ok {
+		return ""
+	}
+
+	switch level {
+	case BumpMajor:
+		major++
+		minor, patch = 0, 0
+	case BumpMinor:
+		minor++
+		patch = 0
+	default:
+		patch++
+	}
+
+	return fmt.Sprintf("v%d.%d.%d", major, minor, patch)
+}
+
+func parseSemver(version string) (major, minor, patch int, ok bool) {
+	if !semver.IsValid(version) {
+		return 0, 0, 0, false
+	}
+
+	core := strings.TrimPrefix(semver.Canonical(version), "v")
+	if i := strings.IndexAny(core, "-+"); i >= 0 {
+		core = core[:i]
+	}
+
+	parts := strings.SplitN(core, ".", 3)
+	if len(parts) != 3 {
+		return 0, 0, 0, false
+	}
+
+	var err error
+
+	if major, err = strconv.Atoi(parts[0]); err != nil {
+		return 0, 0, 0, false
+	}
+
+	if minor, err = strconv.Atoi(parts[1]); err != nil {
+		return 0, 0, 0, false
+	}
+
+	if patch, err = strconv.Atoi(parts[2]); err != nil {
+		return 0, 0, 0, false
+	}
+
+	return major, minor, patch, true
+}
+
+// findCycle returns the modules making up one cycle found within remaining, or nil if remaining's edges
+// (ignoring those already marked broken) are in fact acyclic.
+func findCycle(remaining []*ModuleNode, broken map[*DependencyVertex]bool) []*ModuleNode {
+	remainingSet := make(map[*ModuleNode]bool, len(remaining))
+	for _, node := range remaining {
+		remainingSet[node] = true
+	}
+
+	const (
+		white = 0
+		gray  = 1
+		black = 2
+	)
+
+	color := make(map[*ModuleNode]int, len(remaining))
+
+	var (
+		path  []*ModuleNode
+		found []*ModuleNode
+	)
+
+	var visit func(node *ModuleNode) bool
+
+	visit = func(node *ModuleNode) bool {
+		color[node] = gray
+		path = append(path, node)
+
+		for _, dep := range node.Requires {
+			if broken[dep] || !remainingSet[dep.targetModule] {
+				continue
+			}
+
+			switch color[dep.targetModule] {
+			case gray:
+				for i, n := range path {
+					if n == dep.targetModule {
+						found = append([]*ModuleNode{}, path[i:]...)
+
+						break
+					}
+				}
+
+				return true
+			case white:
+				if visit(dep.targetModule) {
+					return true
+				}
+			}
+		}
+
+		path = path[:len(path)-1]
+		color[node] = black
+
+		return false
+	}
+
+	for _, node := range remaining {
+		if color[node] == white {
+			if visit(node) {
+				return found
+			}
+		}
+	}
+
+	return nil
+}
+
+// weakestEdge returns the module in cycle requiring the lowest semver version of its successor, along with the
+// corresponding DependencyVertex, so that edge can be broken to make the release plan's topological sort progress.
+func weakestEdge(cycle []*ModuleNode) (*ModuleNode, *DependencyVertex) {
+	var (
+		from *ModuleNode
+		weak *DependencyVertex
+	)
+
+	for i, node := range cycle {
+		next := cycle[(i+1)%len(cycle)]
+
+		for _, dep := range node.Requires {
+			if dep.targetModule != next {
+				continue
+			}
+
+			if weak == nil || semver.Compare(dep.targetVersion, weak.targetVersion) < 0 {
+				from = node
+				weak = dep
+			}
+		}
+	}
+
+	return from, weak
+}
+
+func moduleNames(nodes []*ModuleNode) []string {
+	names := make([]string, len(nodes))
+	for i, node := range nodes {
+		names[i] = node.ModuleName
+	}
+
+	return names
+}
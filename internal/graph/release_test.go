@@ -0,0 +1,45 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ReleasePlan(t *testing.T) {
+	leaf := &ModuleNode{ModuleName: "example.com/leaf", Version: "v1.1.0"}
+	consumer := &ModuleNode{ModuleName: "example.com/consumer", Version: "v1.0.0"}
+
+	consumer.addDependency(leaf, "v1.0.0", false, "")
+
+	modulesMap := map[string]*ModuleNode{
+		leaf.ModuleName:     leaf,
+		consumer.ModuleName: consumer,
+	}
+
+	candidates, cycles := ReleasePlan(NewDependencyGraph(modulesMap, false))
+
+	require.Empty(t, cycles)
+	require.Len(t, candidates, 1)
+	require.Equal(t, consumer.ModuleName, candidates[0].ModuleName)
+	require.Equal(t, "v1.1.0", candidates[0].SuggestedVersion)
+	require.Equal(t, BumpMinor, candidates[0].Bumps[0].Level)
+}
+
+func Test_ReleasePlan_breaksCycles(t *testing.T) {
+	a := &ModuleNode{ModuleName: "example.com/a", Version: "v1.0.0"}
+	b := &ModuleNode{ModuleName: "example.com/b", Version: "v1.0.0"}
+
+	a.addDependency(b, "v1.0.0", false, "")
+	b.addDependency(a, "v1.0.0", false, "")
+
+	modulesMap := map[string]*ModuleNode{
+		a.ModuleName: a,
+		b.ModuleName: b,
+	}
+
+	_, cycles := ReleasePlan(NewDependencyGraph(modulesMap, false))
+
+	require.Len(t, cycles, 1)
+	require.ElementsMatch(t, []string{a.ModuleName, b.ModuleName}, cycles[0].Modules)
+}
@@ -0,0 +1,390 @@
+package mods
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/mod/modfile"
+)
+
+const (
+	manifestFileName = "manifest.json"
+	refreshRoutines  = 10
+)
+
+// CacheEntry is the sidecar metadata manifest.json keeps for a single project's go.mod, letting Cache tell whether a
+// re-download is actually necessary instead of assuming the cache directory's mere existence means it's filled.
+type CacheEntry struct {
+	BackendName   string    `json:"backendName"`
+	ProjectID     string    `json:"projectId"`
+	ProjectName   string    `json:"projectName"`
+	FileName      string    `json:"fileName"`
+	GoModBlobSHA  string    `json:"goModBlobSha"`
+	ContentSHA256 string    `json:"contentSha256"`
+	Version       string    `json:"version"`
+	FetchedAt     time.Time `json:"fetchedAt"`
+}
+
+func (e CacheEntry) key() string {
+	return e.BackendName + ":" + e.ProjectID
+}
+
+// Cache maintains a directory of downloaded go.mod files alongside a manifest.json tracking, per project, the go.mod
+// blob SHA last seen. This lets Refresh only re-download a project's go.mod when that SHA actually changed, rather
+// than on every run.
+type Cache struct {
+	dir     string
+	mu      sync.Mutex
+	entries map[string]CacheEntry
+}
+
+// OpenCache opens the cache rooted at dir, creating the directory if it does not yet exist. A missing manifest is
+// treated as an empty cache; the manifest file itself is only written by Refresh or VerifyCache.
+func OpenCache(dir string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("creating cache directory %q: %w", dir, err)
+	}
+
+	c := &Cache{dir: dir, entries: make(map[string]CacheEntry)}
+
+	entries, err := readManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		c.entries[entry.key()] = entry
+	}
+
+	return c, nil
+}
+
+func readManifest(dir string) ([]CacheEntry, error) {
+	bytez, err := os.ReadFile(filepath.Join(dir, manifestFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("reading cache manifest: %w", err)
+	}
+
+	var entries []CacheEntry
+
+	if err := json.Unmarshal(bytez, &entries); err != nil {
+		return nil, fmt.Errorf("parsing cache manifest: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (c *Cache) saveManifest() error {
+	entries := make([]CacheEntry, 0, len(c.entries))
+	for _, entry := range c.entries {
+		entries = append(entries, entry)
+	}
+
+	bytez, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding cache manifest: %w", err)
+	}
+
+	return c.writeFileAtomic(manifestFileName, bytez)
+}
+
+// writeFileAtomic writes content to name within the cache directory via a temp file plus rename, so a crash mid-write
+// never leaves a half-written go.mod file or manifest behind for a later run to trip over.
+func (c *Cache) writeFileAtomic(name string, content []byte) error {
+	tmp, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("creating temp file: %w", err)
+	}
+
+	defer os.Remove(tmp.Name()) // no-op once the rename below succeeds
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+
+		return fmt.Errorf("writing temp file: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("closing temp file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), filepath.Join(c.dir, name)); err != nil {
+		return fmt.Errorf("renaming temp file into place: %w", err)
+	}
+
+	return nil
+}
+
+// Refresh brings the cache up to date against backends. A cached entry younger than maxAge is trusted outright and
+// costs no API calls; older entries have their go.mod blob SHA checked, with content only re-downloaded on a
+// mismatch. forceRefresh skips both shortcuts, as used by the -refresh flag. Projects no longer listed by any backend
+// are dropped from the cache.
+func (c *Cache) Refresh(backends []ModFileBackend, maxAge time.Duration, forceRefresh bool) error {
+	var allRefs []ProjectRef
+
+	byName := make(map[string]ModFileBackend, len(backends))
+
+	for _, backend := range backends {
+		refs, err := backend.ListProjects()
+		if err != nil {
+			return fmt.Errorf("listing projects for backend %q: %w", backend.Name(), err)
+		}
+
+		allRefs = append(allRefs, refs...)
+		byName[backend.Name()] = backend
+	}
+
+	log.Info().Msgf("Refreshing cache for %d known projects", len(allRefs))
+
+	seen := make(map[string]bool, len(allRefs))
+
+	var (
+		wg                                   sync.WaitGroup
+		upToDate, refreshed, removed, errCnt int64
+	)
+
+	refresher := func(routineIdx, numRoutines int) {
+		defer wg.Done()
+
+		for i := routineIdx; i < len(allRefs); i += numRoutines {
+			ref := allRefs[i]
+			key := ref.BackendName + ":" + ref.ProjectID
+
+			c.mu.Lock()
+			seen[key] = true
+			existing, hadEntry := c.entries[key]
+			c.mu.Unlock()
+
+			if hadEntry && !forceRefresh && time.Since(existing.FetchedAt) < maxAge {
+				atomic.AddInt64(&upToDate, 1)
+
+				continue
+			}
+
+			backend := byName[ref.BackendName]
+
+			sha, found, err := backend.GoModBlobSHA(ref)
+			if err != nil {
+				log.Error().Msgf("Checking go.mod blob SHA for %q failed: %v", ref.ProjectName, err)
+				atomic.AddInt64(&errCnt, 1)
+
+				continue
+			}
+
+			if !found {
+				if hadEntry {
+					c.mu.Lock()
+					delete(c.entries, key)
+					c.mu.Unlock()
+
+					_ = os.Remove(filepath.Join(c.dir, existing.FileName))
+					atomic.AddInt64(&removed, 1)
+				}
+
+				continue
+			}
+
+			if hadEntry && !forceRefresh && existing.GoModBlobSHA == sha {
+				// The go.mod content itself is unchanged, but tags are not pinned to a go.mod revision: a project
+				// can cut a new release without touching its go.mod at all. Re-query the latest version instead of
+				// just trusting the one already in the manifest, or it would go stale forever.
+				modulePath, err := cachedModulePath(c.dir, existing.FileName)
+				if err != nil {
+					log.Error().Msgf("Reading cached go.mod for %q failed: %v", ref.ProjectName, err)
+					atomic.AddInt64(&errCnt, 1)
+
+					continue
+				}
+
+				version, err := backend.LatestVersion(ref, modulePath)
+				if err != nil {
+					log.Error().Msgf("Checking latest version for %q failed: %v", ref.ProjectName, err)
+					atomic.AddInt64(&errCnt, 1)
+
+					continue
+				}
+
+				existing.Version = version
+				existing.FetchedAt = time.Now()
+
+				c.mu.Lock()
+				c.entries[key] = existing
+				c.mu.Unlock()
+
+				atomic.AddInt64(&upToDate, 1)
+
+				continue
+			}
+
+			content, version, err := backend.FetchModFile(ref)
+			if err != nil {
+				log.Error().Msgf("Fetching go.mod for %q failed: %v", ref.ProjectName, err)
+				atomic.AddInt64(&errCnt, 1)
+
+				continue
+			}
+
+			if content == nil {
+				continue
+			}
+
+			fileName := encodeFilename(ref.BackendName, ref.ProjectID)
+
+			if err := c.writeFileAtomic(fileName, content); err != nil {
+				log.Error().Msgf("Writing go.mod for %q failed: %v", ref.ProjectName, err)
+				atomic.AddInt64(&errCnt, 1)
+
+				continue
+			}
+
+			hashed := sha256.Sum256(content)
+
+			c.mu.Lock()
+			c.entries[key] = CacheEntry{
+				BackendName:   ref.BackendName,
+				ProjectID:     ref.ProjectID,
+				ProjectName:   ref.ProjectName,
+				FileName:      fileName,
+				GoModBlobSHA:  sha,
+				ContentSHA256: hex.EncodeToString(hashed[:]),
+				Version:       version,
+				FetchedAt:     time.Now(),
+			}
+			c.mu.Unlock()
+
+			atomic.AddInt64(&refreshed, 1)
+		}
+	}
+
+	wg.Add(refreshRoutines)
+
+	for i := 0; i < refreshRoutines; i++ {
+		go refresher(i, refreshRoutines)
+	}
+
+	wg.Wait()
+
+	c.mu.Lock()
+
+	for key, entry := range c.entries {
+		if !seen[key] {
+			delete(c.entries, key)
+
+			_ = os.Remove(filepath.Join(c.dir, entry.FileName))
+			removed++
+		}
+	}
+
+	c.mu.Unlock()
+
+	log.Info().Msgf("Cache refresh done: %d up to date, %d (re)downloaded, %d removed, %d errors", upToDate, refreshed, removed, errCnt)
+
+	return c.saveManifest()
+}
+
+// cachedModulePath reads the module path out of the already-cached go.mod file named fileName within dir, so the
+// latest-version lookup can apply its major-version-suffix filtering without re-downloading content known unchanged.
+func cachedModulePath(dir, fileName string) (string, error) {
+	bytez, err := os.ReadFile(filepath.Join(dir, fileName))
+	if err != nil {
+		return "", fmt.Errorf("reading cached go.mod: %w", err)
+	}
+
+	return modfile.ModulePath(bytez), nil
+}
+
+// ModFiles returns the parsed go.mod content of every project currently tracked in the cache.
+func (c *Cache) ModFiles() ([]*Module, error) {
+	c.mu.Lock()
+	entries := make([]CacheEntry, 0, len(c.entries))
+	for _, entry := range c.entries {
+		entries = append(entries, entry)
+	}
+	c.mu.Unlock()
+
+	modules := make([]*Module, 0, len(entries))
+
+	for _, entry := range entries {
+		filePath := filepath.Join(c.dir, entry.FileName)
+
+		bytez, err := os.ReadFile(filePath)
+		if err != nil {
+			log.Error().Msgf("Could not read cached mod file for %q: %v", entry.ProjectName, err)
+
+			continue
+		}
+
+		file, err := modfile.Parse(entry.FileName, bytez, nil)
+		if err != nil {
+			log.Error().Msgf("Could not parse cached mod file for %q: %v", entry.ProjectName, err)
+
+			continue
+		}
+
+		modules = append(modules, &Module{ModFile: file, Version: entry.Version, Origin: entry.BackendName})
+	}
+
+	return modules, nil
+}
+
+// VerifyCache re-hashes every cached file against the SHA-256 recorded for it in manifest.json, pruning (both from
+// the manifest and from disk) any entry whose file is missing or no longer matches its recorded hash.
+func VerifyCache(dir string) error {
+	c, err := OpenCache(dir)
+	if err != nil {
+		return err
+	}
+
+	var pruned int
+
+	for key, entry := range c.entries {
+		filePath := filepath.Join(c.dir, entry.FileName)
+
+		bytez, err := os.ReadFile(filePath)
+		if err != nil {
+			log.Error().Msgf("Cache entry for %q points at an unreadable file, pruning: %v", entry.ProjectName, err)
+
+			delete(c.entries, key)
+			pruned++
+
+			continue
+		}
+
+		hashed := sha256.Sum256(bytez)
+
+		if hex.EncodeToString(hashed[:]) != entry.ContentSHA256 {
+			log.Error().Msgf("Cache entry for %q is corrupted (content hash mismatch), pruning", entry.ProjectName)
+
+			_ = os.Remove(filePath)
+			delete(c.entries, key)
+			pruned++
+		}
+	}
+
+	if pruned > 0 {
+		log.Info().Msgf("VerifyCache pruned %d corrupted entries", pruned)
+	}
+
+	return c.saveManifest()
+}
+
+// encodeFilename derives the on-disk file name a project's go.mod is cached under. It is keyed by backend and
+// project identity only (not by version), so repeated refreshes of the same project overwrite a single file instead
+// of leaving stale versions behind.
+func encodeFilename(backendName, projectID string) string {
+	hashed := sha256.Sum256([]byte(backendName + ":" + projectID))
+
+	return fmt.Sprintf("%s__%s", backendName, hex.EncodeToString(hashed[:]))
+}
@@ -0,0 +1,186 @@
+package mods
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBackend struct {
+	name               string
+	refs               []ProjectRef
+	blobSHAs           map[string]string
+	contents           map[string][]byte
+	version            string
+	blobSHACalls       int
+	fetchCalls         int
+	latestVersionCalls int
+}
+
+func (f *fakeBackend) Name() string { return f.name }
+
+func (f *fakeBackend) ListProjects() ([]ProjectRef, error) { return f.refs, nil }
+
+func (f *fakeBackend) GoModBlobSHA(ref ProjectRef) (string, bool, error) {
+	f.blobSHACalls++
+
+	sha, ok := f.blobSHAs[ref.ProjectID]
+
+	return sha, ok, nil
+}
+
+func (f *fakeBackend) FetchModFile(ref ProjectRef) ([]byte, string, error) {
+	f.fetchCalls++
+
+	return f.contents[ref.ProjectID], f.version, nil
+}
+
+func (f *fakeBackend) LatestVersion(ref ProjectRef, modulePath string) (string, error) {
+	f.latestVersionCalls++
+
+	return f.version, nil
+}
+
+func Test_Cache_Refresh_downloadsOnceThenSkipsUnchanged(t *testing.T) {
+	dir := t.TempDir()
+
+	backend := &fakeBackend{
+		name:     "gitlab",
+		refs:     []ProjectRef{{BackendName: "gitlab", ProjectID: "1", ProjectName: "team/service", Ref: "main"}},
+		blobSHAs: map[string]string{"1": "sha-v1"},
+		contents: map[string][]byte{"1": []byte("module example.com/service\n\ngo 1.21\n")},
+		version:  "v1.0.0",
+	}
+
+	cache, err := OpenCache(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, cache.Refresh([]ModFileBackend{backend}, time.Hour, false))
+	require.Equal(t, 1, backend.fetchCalls)
+
+	modules, err := cache.ModFiles()
+	require.NoError(t, err)
+	require.Len(t, modules, 1)
+	require.Equal(t, "v1.0.0", modules[0].Version)
+	require.Equal(t, "gitlab", modules[0].Origin)
+
+	// Re-opening and refreshing again should not re-download, as the entry is still within maxAge.
+	cache, err = OpenCache(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, cache.Refresh([]ModFileBackend{backend}, time.Hour, false))
+	require.Equal(t, 1, backend.fetchCalls)
+}
+
+func Test_Cache_Refresh_redownloadsOnBlobSHAChange(t *testing.T) {
+	dir := t.TempDir()
+
+	backend := &fakeBackend{
+		name:     "gitlab",
+		refs:     []ProjectRef{{BackendName: "gitlab", ProjectID: "1", ProjectName: "team/service", Ref: "main"}},
+		blobSHAs: map[string]string{"1": "sha-v1"},
+		contents: map[string][]byte{"1": []byte("module example.com/service\n\ngo 1.21\n")},
+		version:  "v1.0.0",
+	}
+
+	cache, err := OpenCache(dir)
+	require.NoError(t, err)
+	require.NoError(t, cache.Refresh([]ModFileBackend{backend}, 0, false))
+	require.Equal(t, 1, backend.fetchCalls)
+
+	backend.blobSHAs["1"] = "sha-v2"
+	backend.contents["1"] = []byte("module example.com/service\n\ngo 1.22\n")
+	backend.version = "v1.1.0"
+
+	require.NoError(t, cache.Refresh([]ModFileBackend{backend}, 0, false))
+	require.Equal(t, 2, backend.fetchCalls)
+
+	modules, err := cache.ModFiles()
+	require.NoError(t, err)
+	require.Len(t, modules, 1)
+	require.Equal(t, "v1.1.0", modules[0].Version)
+}
+
+func Test_Cache_Refresh_refreshesVersionEvenWhenBlobUnchanged(t *testing.T) {
+	dir := t.TempDir()
+
+	backend := &fakeBackend{
+		name:     "gitlab",
+		refs:     []ProjectRef{{BackendName: "gitlab", ProjectID: "1", ProjectName: "team/service", Ref: "main"}},
+		blobSHAs: map[string]string{"1": "sha-v1"},
+		contents: map[string][]byte{"1": []byte("module example.com/service\n\ngo 1.21\n")},
+		version:  "v1.0.0",
+	}
+
+	cache, err := OpenCache(dir)
+	require.NoError(t, err)
+	require.NoError(t, cache.Refresh([]ModFileBackend{backend}, 0, false))
+	require.Equal(t, 1, backend.fetchCalls)
+
+	// A new tag was cut without touching go.mod: the blob SHA stays the same, but Version must not go stale.
+	backend.version = "v1.1.0"
+
+	require.NoError(t, cache.Refresh([]ModFileBackend{backend}, 0, false))
+	require.Equal(t, 1, backend.fetchCalls)
+	require.Equal(t, 1, backend.latestVersionCalls)
+
+	modules, err := cache.ModFiles()
+	require.NoError(t, err)
+	require.Len(t, modules, 1)
+	require.Equal(t, "v1.1.0", modules[0].Version)
+}
+
+func Test_Cache_Refresh_removesProjectsNoLongerListed(t *testing.T) {
+	dir := t.TempDir()
+
+	backend := &fakeBackend{
+		name:     "gitlab",
+		refs:     []ProjectRef{{BackendName: "gitlab", ProjectID: "1", ProjectName: "team/service", Ref: "main"}},
+		blobSHAs: map[string]string{"1": "sha-v1"},
+		contents: map[string][]byte{"1": []byte("module example.com/service\n\ngo 1.21\n")},
+		version:  "v1.0.0",
+	}
+
+	cache, err := OpenCache(dir)
+	require.NoError(t, err)
+	require.NoError(t, cache.Refresh([]ModFileBackend{backend}, 0, false))
+
+	backend.refs = nil
+
+	require.NoError(t, cache.Refresh([]ModFileBackend{backend}, 0, false))
+
+	modules, err := cache.ModFiles()
+	require.NoError(t, err)
+	require.Empty(t, modules)
+}
+
+func Test_VerifyCache_prunesCorruptedEntries(t *testing.T) {
+	dir := t.TempDir()
+
+	backend := &fakeBackend{
+		name:     "gitlab",
+		refs:     []ProjectRef{{BackendName: "gitlab", ProjectID: "1", ProjectName: "team/service", Ref: "main"}},
+		blobSHAs: map[string]string{"1": "sha-v1"},
+		contents: map[string][]byte{"1": []byte("module example.com/service\n\ngo 1.21\n")},
+		version:  "v1.0.0",
+	}
+
+	cache, err := OpenCache(dir)
+	require.NoError(t, err)
+	require.NoError(t, cache.Refresh([]ModFileBackend{backend}, 0, false))
+
+	var fileName string
+	for _, entry := range cache.entries {
+		fileName = entry.FileName
+	}
+
+	require.NoError(t, os.WriteFile(dir+"/"+fileName, []byte("tampered"), 0o600))
+
+	require.NoError(t, VerifyCache(dir))
+
+	cache, err = OpenCache(dir)
+	require.NoError(t, err)
+	require.Empty(t, cache.entries)
+}
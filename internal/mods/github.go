@@ -0,0 +1,203 @@
+package mods
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v58/github"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/mod/modfile"
+)
+
+const (
+	githubSearchPerPage = 100
+	githubTagsPerPage   = 100
+)
+
+// GitHubModFetcher is a ModFileBackend fetching go.mod files from repositories hosted on GitHub (or a GitHub
+// Enterprise instance, depending on how ghClient was configured). Repositories are discovered via the GitHub repository
+// search API, scoped to an org or user and optionally filtered by topic, mirroring how teams typically tag their
+// Go module repositories.
+type GitHubModFetcher struct {
+	name            string
+	ghClient        *github.Client
+	owner           string
+	topics          []string
+	skipPrereleases bool
+}
+
+// NewGitHubModFetcher creates a GitHubModFetcher. owner is either an organization or a user login, searched via
+// GitHub's "org:"/"user:" search qualifier depending on what ownerIsOrg states. topics, if non-empty, restricts the
+// search to repositories tagged with all of the given topics.
+func NewGitHubModFetcher(name string, ghClient *github.Client, owner string, ownerIsOrg bool, topics []string, skipPrereleases bool) *GitHubModFetcher {
+	qualifier := "user"
+
+	if ownerIsOrg {
+		qualifier = "org"
+	}
+
+	return &GitHubModFetcher{
+		name:            name,
+		ghClient:        ghClient,
+		owner:           fmt.Sprintf("%s:%s", qualifier, owner),
+		topics:          topics,
+		skipPrereleases: skipPrereleases,
+	}
+}
+
+func (g *GitHubModFetcher) Name() string {
+	return g.name
+}
+
+func (g *GitHubModFetcher) ListProjects() ([]ProjectRef, error) {
+	repos, err := g.fetchAllRepos()
+	if err != nil {
+		return nil, fmt.Errorf("fetching repositories from GitHub: %w", err)
+	}
+
+	refs := make([]ProjectRef, 0, len(repos))
+
+	for _, repo := range repos {
+		refs = append(refs, ProjectRef{
+			BackendName: g.name,
+			ProjectID:   repo.GetFullName(),
+			ProjectName: repo.GetFullName(),
+			Ref:         repo.GetDefaultBranch(),
+		})
+	}
+
+	return refs, nil
+}
+
+func (g *GitHubModFetcher) GoModBlobSHA(ref ProjectRef) (string, bool, error) {
+	owner, name, ok := splitOwnerRepo(ref.ProjectID)
+	if !ok {
+		return "", false, fmt.Errorf("project ID %q is not a valid owner/repo full name", ref.ProjectID)
+	}
+
+	fileContent, _, resp, err := g.ghClient.Repositories.GetContents(context.Background(), owner, name, "go.mod", &github.RepositoryContentGetOptions{Ref: ref.Ref})
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return "", false, nil
+		}
+
+		return "", false, err
+	}
+
+	return fileContent.GetSHA(), true, nil
+}
+
+func (g *GitHubModFetcher) FetchModFile(ref ProjectRef) ([]byte, string, error) {
+	owner, name, ok := splitOwnerRepo(ref.ProjectID)
+	if !ok {
+		return nil, "", fmt.Errorf("project ID %q is not a valid owner/repo full name", ref.ProjectID)
+	}
+
+	fileContent, _, resp, err := g.ghClient.Repositories.GetContents(context.Background(), owner, name, "go.mod", &github.RepositoryContentGetOptions{Ref: ref.Ref})
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, "", nil
+		}
+
+		return nil, "", err
+	}
+
+	rawFile, err := fileContent.GetContent()
+	if err != nil {
+		return nil, "", fmt.Errorf("decoding go.mod content for repository %q: %w", ref.ProjectName, err)
+	}
+
+	version, err := g.latestVersion(owner, name, modfile.ModulePath([]byte(rawFile)))
+	if err != nil {
+		log.Error().Msgf("Could not get latest version of repository %q: %v", ref.ProjectName, err)
+	}
+
+	return []byte(rawFile), version, nil
+}
+
+func (g *GitHubModFetcher) LatestVersion(ref ProjectRef, modulePath string) (string, error) {
+	owner, name, ok := splitOwnerRepo(ref.ProjectID)
+	if !ok {
+		return "", fmt.Errorf("project ID %q is not a valid owner/repo full name", ref.ProjectID)
+	}
+
+	return g.latestVersion(owner, name, modulePath)
+}
+
+func splitOwnerRepo(fullName string) (owner, name string, ok bool) {
+	idx := strings.IndexByte(fullName, '/')
+	if idx < 0 {
+		return "", "", false
+	}
+
+	return fullName[:idx], fullName[idx+1:], true
+}
+
+func (g *GitHubModFetcher) fetchAllRepos() ([]*github.Repository, error) {
+	query := g.owner
+
+	for _, topic := range g.topics {
+		query += fmt.Sprintf(" topic:%s", topic)
+	}
+
+	opts := &github.SearchOptions{
+		ListOptions: github.ListOptions{PerPage: githubSearchPerPage},
+	}
+
+	var allRepos []*github.Repository
+
+	for {
+		result, resp, err := g.ghClient.Search.Repositories(context.Background(), query, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		allRepos = append(allRepos, result.Repositories...)
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opts.Page = resp.NextPage
+	}
+
+	return allRepos, nil
+}
+
+// latestVersion returns the highest semver-valid tag applicable to modulePath, reusing the same selection logic as
+// the GitLab backend.
+func (g *GitHubModFetcher) latestVersion(owner, repo, modulePath string) (string, error) {
+	tagNames, err := g.fetchAllTagNames(owner, repo)
+	if err != nil {
+		return "n.a.", err
+	}
+
+	return SelectLatestVersion(tagNames, modulePath, SelectLatestVersionOptions{SkipPrereleases: g.skipPrereleases}), nil
+}
+
+func (g *GitHubModFetcher) fetchAllTagNames(owner, repo string) ([]string, error) {
+	opts := &github.ListOptions{PerPage: githubTagsPerPage}
+
+	var tagNames []string
+
+	for {
+		tags, resp, err := g.ghClient.Repositories.ListTags(context.Background(), owner, repo, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tag := range tags {
+			tagNames = append(tagNames, tag.GetName())
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opts.Page = resp.NextPage
+	}
+
+	return tagNames, nil
+}
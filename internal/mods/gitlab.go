@@ -3,94 +3,104 @@ package mods
 import (
 	"fmt"
 	"net/http"
-	"sync"
-	"sync/atomic"
+	"strconv"
 
 	"github.com/rs/zerolog/log"
 	"github.com/xanzy/go-gitlab"
+	"golang.org/x/mod/modfile"
 )
 
 const (
 	paginationProjectsPerPage = 100
-	downloadRoutines          = 10
+	paginationTagsPerPage     = 100
 )
 
 type GitLabModFetcher struct {
-	glClient *gitlab.Client
+	name            string
+	glClient        *gitlab.Client
+	skipPrereleases bool
 }
 
-func NewGitLabModFetcher(glClient *gitlab.Client) *GitLabModFetcher {
+func NewGitLabModFetcher(name string, glClient *gitlab.Client, skipPrereleases bool) *GitLabModFetcher {
 	return &GitLabModFetcher{
-		glClient: glClient,
+		name:            name,
+		glClient:        glClient,
+		skipPrereleases: skipPrereleases,
 	}
 }
 
-func (g *GitLabModFetcher) ProvideModFilesAndVersions(storeModFile StoreModFileFn) error {
+func (g *GitLabModFetcher) Name() string {
+	return g.name
+}
+
+func (g *GitLabModFetcher) ListProjects() ([]ProjectRef, error) {
 	projects, err := g.fetchAllProjects()
 	if err != nil {
-		return fmt.Errorf("fetching projects from GitLab: %w", err)
+		return nil, fmt.Errorf("fetching projects from GitLab: %w", err)
 	}
 
-	log.Info().Msgf("Going to check %d projects for go.mod files and released versions/tags", len(projects))
+	refs := make([]ProjectRef, 0, len(projects))
 
-	if err := g.downloadModFilesAndLookupVersions(projects, storeModFile); err != nil {
-		return fmt.Errorf("fetching module metadata: %w", err)
+	for _, project := range projects {
+		refs = append(refs, ProjectRef{
+			BackendName: g.name,
+			ProjectID:   strconv.Itoa(project.ID),
+			ProjectName: project.NameWithNamespace,
+			Ref:         project.DefaultBranch,
+		})
 	}
 
-	return nil
+	return refs, nil
 }
 
-func (g *GitLabModFetcher) downloadModFilesAndLookupVersions(projects []*gitlab.Project, storeModFile StoreModFileFn) error {
-	var (
-		noErrorCnt, noModFile int64
-		wg                    sync.WaitGroup
-	)
-
-	downloader := func(routineIdx, numRoutines int) {
-		for i := routineIdx; i < len(projects); i = i + numRoutines {
-			project := projects[i]
-
-			rawFile, resp, err := g.glClient.RepositoryFiles.GetRawFile(project.ID, "go.mod", nil)
-			if err != nil {
-				if resp.StatusCode == http.StatusNotFound {
-					atomic.AddInt64(&noModFile, 1)
-
-					continue
-				}
-
-				log.Error().Msgf("Failed to download go.mod for project %q: %v", project.NameWithNamespace, err)
+func (g *GitLabModFetcher) GoModBlobSHA(ref ProjectRef) (string, bool, error) {
+	projectID, err := strconv.Atoi(ref.ProjectID)
+	if err != nil {
+		return "", false, fmt.Errorf("parsing project ID %q: %w", ref.ProjectID, err)
+	}
 
-				continue
-			}
+	file, resp, err := g.glClient.RepositoryFiles.GetFile(projectID, "go.mod", &gitlab.GetFileOptions{Ref: &ref.Ref})
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return "", false, nil
+		}
 
-			version, err := g.latestVersion(project.ID)
-			if err != nil {
-				log.Error().Msgf("Could not get latest version of project %q: %v", project.Name, err)
-			}
+		return "", false, err
+	}
 
-			if err := storeModFile(project.NameWithNamespace, version, rawFile); err != nil {
-				log.Error().Msgf("Failed to store mod file: %v", err)
+	return file.BlobID, true, nil
+}
 
-				continue
-			}
+func (g *GitLabModFetcher) FetchModFile(ref ProjectRef) ([]byte, string, error) {
+	projectID, err := strconv.Atoi(ref.ProjectID)
+	if err != nil {
+		return nil, "", fmt.Errorf("parsing project ID %q: %w", ref.ProjectID, err)
+	}
 
-			atomic.AddInt64(&noErrorCnt, 1)
+	rawFile, resp, err := g.glClient.RepositoryFiles.GetRawFile(projectID, "go.mod", &gitlab.GetRawFileOptions{Ref: &ref.Ref})
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, "", nil
 		}
 
-		wg.Done()
+		return nil, "", err
 	}
 
-	wg.Add(downloadRoutines)
-
-	for i := 0; i < downloadRoutines; i++ {
-		go downloader(i, downloadRoutines)
+	version, err := g.latestVersion(projectID, modfile.ModulePath(rawFile))
+	if err != nil {
+		log.Error().Msgf("Could not get latest version of project %q: %v", ref.ProjectName, err)
 	}
 
-	wg.Wait()
+	return rawFile, version, nil
+}
 
-	log.Info().Msgf("%d repositories contain no go.mod file. Downloaded %d files, %d errors occurred.", noModFile, noErrorCnt, int64(len(projects))-noErrorCnt-noModFile)
+func (g *GitLabModFetcher) LatestVersion(ref ProjectRef, modulePath string) (string, error) {
+	projectID, err := strconv.Atoi(ref.ProjectID)
+	if err != nil {
+		return "", fmt.Errorf("parsing project ID %q: %w", ref.ProjectID, err)
+	}
 
-	return nil
+	return g.latestVersion(projectID, modulePath)
 }
 
 func (g *GitLabModFetcher) fetchAllProjects() ([]*gitlab.Project, error) {
@@ -125,28 +135,44 @@ func (g *GitLabModFetcher) fetchAllProjects() ([]*gitlab.Project, error) {
 	return allProjects, nil
 }
 
-var (
-	orderByUpdated   = "updated"
-	sortDescending   = "desc"
-	glListTagOptions = &gitlab.ListTagsOptions{
+// latestVersion returns the highest semver-valid tag applicable to modulePath. We fetch all tags rather than
+// relying on GitLab's "most recently updated" ordering, as that routinely surfaces non-semver tags, release
+// candidates or backport patches ahead of the actual latest release.
+func (g *GitLabModFetcher) latestVersion(projectID int, modulePath string) (string, error) {
+	tagNames, err := g.fetchAllTagNames(projectID)
+	if err != nil {
+		return "n.a.", err
+	}
+
+	return SelectLatestVersion(tagNames, modulePath, SelectLatestVersionOptions{SkipPrereleases: g.skipPrereleases}), nil
+}
+
+func (g *GitLabModFetcher) fetchAllTagNames(projectID int) ([]string, error) {
+	listOptions := &gitlab.ListTagsOptions{
 		ListOptions: gitlab.ListOptions{
 			Page:    1, // first page
-			PerPage: 1,
+			PerPage: paginationTagsPerPage,
 		},
-		OrderBy: &orderByUpdated,
-		Sort:    &sortDescending,
 	}
-)
 
-func (g *GitLabModFetcher) latestVersion(projectID int) (string, error) {
-	tags, _, err := g.glClient.Tags.ListTags(projectID, glListTagOptions)
-	if err != nil {
-		return "n.a.", err
-	}
+	var tagNames []string
 
-	if len(tags) == 0 {
-		return "", nil
+	for {
+		tags, resp, err := g.glClient.Tags.ListTags(projectID, listOptions)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tag := range tags {
+			tagNames = append(tagNames, tag.Name)
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		listOptions.Page = resp.NextPage
 	}
 
-	return tags[0].Name, nil
+	return tagNames, nil
 }
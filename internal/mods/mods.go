@@ -1,100 +1,43 @@
 package mods
 
-import (
-	"crypto/sha256"
-	"encoding/hex"
-	"errors"
-	"fmt"
-	"os"
-	"path"
-	"strings"
-
-	"github.com/rs/zerolog/log"
-	"golang.org/x/mod/modfile"
-)
-
-type StoreModFileFn func(projectName string, moduleVersion string, modFileContent []byte) error
-
-type ModFileBackend interface {
-	ProvideModFilesAndVersions(storeModFile StoreModFileFn) error
-}
-
-func Download(backend ModFileBackend, downloadDir string) error {
-	return backend.ProvideModFilesAndVersions(func(projectName string, version string, modFileContent []byte) error {
-		filePath := path.Join(downloadDir, encodeFilename(projectName, version))
-
-		return os.WriteFile(filePath, modFileContent, 0o600)
-	})
+import "golang.org/x/mod/modfile"
+
+// ProjectRef identifies a single project/repository known to a ModFileBackend. It is cheap to obtain via
+// ListProjects, without downloading (or even checking the existence of) the project's go.mod file.
+type ProjectRef struct {
+	// BackendName is the name of the ModFileBackend that produced this ref, e.g. "gitlab" or "github". It is used
+	// to keep projects of the same name on different backends from colliding, and to route a ref back to the
+	// backend that can resolve it.
+	BackendName string
+	// ProjectID uniquely identifies the project within its backend (e.g. a GitLab numeric project ID, or a GitHub
+	// "owner/repo" full name).
+	ProjectID string
+	// ProjectName is the project's human-readable display name, e.g. "team/service" or "org/repo".
+	ProjectName string
+	// Ref is the branch (or commit) go.mod content should be read from, typically the project's default branch.
+	Ref string
 }
 
-func encodeFilename(projectName, version string) string {
-	// Just generate something unique, we do not need to retrieve the project name from the filename later
-	hashedProjectName := sha256.Sum256([]byte(projectName))
-
-	return fmt.Sprintf("%s_%s",
-		hex.EncodeToString(hashedProjectName[:]),
-		hex.EncodeToString([]byte(version)[:]))
-}
-
-func decodeFilename(filename string) (string, error) {
-	splits := strings.Split(filename, "_")
-
-	if len(splits) != 2 {
-		return "", errors.New("filename does not follow pattern <hex>_<hex>")
-	}
-
-	bytez, err := hex.DecodeString(splits[1])
-	if err != nil {
-		return "", fmt.Errorf("failed decoding version: %w", err)
-	}
-
-	return string(bytez), nil
+// ModFileBackend discovers projects hosting a go.mod file. Listing projects is split from fetching their go.mod
+// content so that callers such as Cache can cheaply check whether a project's go.mod has actually changed before
+// paying for a full download.
+type ModFileBackend interface {
+	Name() string
+	ListProjects() ([]ProjectRef, error)
+	// GoModBlobSHA returns the content-addressed hash the backend assigns the project's go.mod blob at ref, without
+	// downloading the file itself. found is false if the project carries no go.mod at ref.
+	GoModBlobSHA(ref ProjectRef) (sha string, found bool, err error)
+	// FetchModFile downloads the project's go.mod content along with its latest released version.
+	FetchModFile(ref ProjectRef) (content []byte, version string, err error)
+	// LatestVersion returns the project's latest released version without re-downloading its go.mod content. Tags
+	// are not pinned to a particular go.mod revision, so this can - and should - be re-queried even when
+	// GoModBlobSHA reports the go.mod itself is unchanged.
+	LatestVersion(ref ProjectRef, modulePath string) (string, error)
 }
 
 type Module struct {
 	ModFile *modfile.File
 	Version string
-}
-
-func ReadModFiles(modFilesDir string) ([]*Module, error) {
-	var modFiles []*Module
-
-	dirEntries, err := os.ReadDir(modFilesDir)
-	if err != nil {
-		return nil, fmt.Errorf("reading contents of download dir: %w", err)
-	}
-
-	for _, entry := range dirEntries {
-		if !entry.Type().IsRegular() {
-			continue
-		}
-
-		version, err := decodeFilename(entry.Name())
-		if err != nil {
-			return nil, fmt.Errorf("decoding filename: %w", err)
-		}
-
-		filePath := path.Join(modFilesDir, entry.Name())
-
-		bytez, err := os.ReadFile(filePath)
-		if err != nil {
-			log.Error().Msgf("Could not read mod file %q: %v", filePath, err)
-
-			continue
-		}
-
-		file, err := modfile.Parse(entry.Name(), bytez, nil)
-		if err != nil {
-			log.Error().Msgf("Could not parse mod file %q: %v", filePath, err)
-
-			continue
-		}
-
-		modFiles = append(modFiles, &Module{
-			ModFile: file,
-			Version: version,
-		})
-	}
-
-	return modFiles, nil
+	// Origin is the name of the backend (e.g. "gitlab" or "github") the module was fetched from.
+	Origin string
 }
@@ -6,14 +6,16 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func Test_encodeFilename(t *testing.T) {
-	version := "v1.0.0"
-	projectName := "dummy/project"
+func Test_encodeFilename_stableForSameProject(t *testing.T) {
+	first := encodeFilename("gitlab", "dummy/project")
+	second := encodeFilename("gitlab", "dummy/project")
 
-	filename := encodeFilename(projectName, version)
+	require.Equal(t, first, second)
+}
 
-	decodedVersion, err := decodeFilename(filename)
+func Test_encodeFilename_sameProjectIDDifferentBackend(t *testing.T) {
+	gitlabFilename := encodeFilename("gitlab", "dummy/project")
+	githubFilename := encodeFilename("github", "dummy/project")
 
-	require.NoError(t, err)
-	require.Equal(t, version, decodedVersion)
+	require.NotEqual(t, gitlabFilename, githubFilename)
 }
@@ -0,0 +1,75 @@
+package mods
+
+import (
+	"strings"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// SelectLatestVersionOptions configures SelectLatestVersion.
+type SelectLatestVersionOptions struct {
+	// SkipPrereleases excludes tags carrying a semver pre-release component (e.g. "v1.2.0-rc.1") from consideration.
+	SkipPrereleases bool
+}
+
+// SelectLatestVersion picks the highest semver-valid tag out of tags that is applicable to modulePath, normalizing
+// an optional leading "v" along the way. Tags that are not valid semver, that are pre-releases (if
+// opts.SkipPrereleases is set), or whose major version does not match modulePath's major version suffix are
+// ignored. For a modulePath without a `/vN` suffix only v0 and v1 tags are considered, mirroring the go command's
+// rule that v2+ modules must be versioned via a suffixed module path. Returns "" if no tag qualifies.
+func SelectLatestVersion(tags []string, modulePath string, opts SelectLatestVersionOptions) string {
+	expectedMajor := expectedMajorFor(modulePath)
+
+	var best string
+
+	for _, tag := range tags {
+		candidate := tag
+
+		if !strings.HasPrefix(candidate, "v") {
+			candidate = "v" + candidate
+		}
+
+		if !semver.IsValid(candidate) {
+			continue
+		}
+
+		if opts.SkipPrereleases && semver.Prerelease(candidate) != "" {
+			continue
+		}
+
+		major := semver.Major(candidate)
+
+		if expectedMajor != "" {
+			if major != expectedMajor {
+				continue
+			}
+		} else if major != "v0" && major != "v1" {
+			continue
+		}
+
+		if best == "" || semver.Compare(candidate, best) > 0 {
+			best = candidate
+		}
+	}
+
+	return best
+}
+
+// expectedMajorFor returns the major version (e.g. "v2") a tag must carry to be a valid release of modulePath, or ""
+// if modulePath carries no `/vN` (or gopkg.in-style `.vN`) suffix, meaning only v0/v1 tags apply.
+func expectedMajorFor(modulePath string) string {
+	_, pathMajor, ok := module.SplitPathVersion(modulePath)
+	if !ok || pathMajor == "" {
+		return ""
+	}
+
+	pathMajor = strings.TrimPrefix(pathMajor, "/")
+	pathMajor = strings.TrimPrefix(pathMajor, ".")
+
+	if pathMajor == "" {
+		return ""
+	}
+
+	return "v" + strings.TrimPrefix(pathMajor, "v")
+}
@@ -0,0 +1,16 @@
+package mods
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SelectLatestVersion(t *testing.T) {
+	tags := []string{"v1.2.0", "1.3.0", "v1.4.0-rc.1", "not-a-version", "v2.0.0"}
+
+	require.Equal(t, "v1.4.0-rc.1", SelectLatestVersion(tags, "github.com/foo/bar", SelectLatestVersionOptions{}))
+	require.Equal(t, "v1.3.0", SelectLatestVersion(tags, "github.com/foo/bar", SelectLatestVersionOptions{SkipPrereleases: true}))
+	require.Equal(t, "v2.0.0", SelectLatestVersion(tags, "github.com/foo/bar/v2", SelectLatestVersionOptions{}))
+	require.Equal(t, "", SelectLatestVersion(tags, "github.com/foo/bar/v3", SelectLatestVersionOptions{}))
+}
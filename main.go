@@ -1,36 +1,52 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/google/go-github/v58/github"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/xanzy/go-gitlab"
+	"golang.org/x/oauth2"
 
 	"github.com/florianloch/gomodgraph/internal/graph"
 	"github.com/florianloch/gomodgraph/internal/mods"
 )
 
-// TODO: Consider "Replace" directive in go.mod
-
 const (
 	glTokenEnvVar   = "GITLAB_API_TOKEN"
 	glBaseURLEnvVar = "GITLAB_BASE_URL"
+	ghTokenEnvVar   = "GITHUB_TOKEN"
 	tmpDir          = "/tmp/gomodgraph/"
 	goModDir        = tmpDir + "go_mod_files" // has to be below tmpDir
+
+	gitlabBackendName = "gitlab"
+	githubBackendName = "github"
 )
 
 type config struct {
-	glToken          string
-	glBaseURL        string
-	homeModule       string
-	goRegistryPrefix string
+	glToken         string
+	glBaseURL       string
+	ghToken         string
+	ghOwner         string
+	ghOwnerOrg      bool
+	ghTopics        []string
+	skipPrereleases bool
+	homeModule      string
+	// registryPrefixes maps a backend name (see gitlabBackendName/githubBackendName) to the registry host prefix
+	// module names fetched from that backend start with.
+	registryPrefixes map[string]string
 	cleanup          bool
+	refresh          bool
+	maxAge           time.Duration
 	listenAddr       string
 }
 
@@ -52,27 +68,25 @@ func main() {
 		log.Fatal().Msgf("Initializing GitLab client: %v", err)
 	}
 
-	var cacheFilled bool
+	backends := []mods.ModFileBackend{mods.NewGitLabModFetcher(gitlabBackendName, glClient, cfg.skipPrereleases)}
 
-	if info, err := os.Stat(goModDir); err == nil && info.IsDir() {
-		cacheFilled = true // we simply assume the cache is filled in case the cache directory for the mod files exists
-	}
+	if cfg.ghToken != "" {
+		ghHTTPClient := oauth2.NewClient(context.Background(), oauth2.StaticTokenSource(&oauth2.Token{AccessToken: cfg.ghToken}))
+		ghClient := github.NewClient(ghHTTPClient)
 
-	if err := os.MkdirAll(goModDir, 0o700); err != nil {
-		log.Fatal().Msgf("Directory for downloaded mod files (%q) cannot be accesses and could not be created: %v",
-			goModDir,
-			err)
+		backends = append(backends, mods.NewGitHubModFetcher(githubBackendName, ghClient, cfg.ghOwner, cfg.ghOwnerOrg, cfg.ghTopics, cfg.skipPrereleases))
 	}
 
-	if !cacheFilled {
-		log.Info().Msgf("Cache at %q is empty, will scan for projects and download mod files", goModDir)
+	cache, err := mods.OpenCache(goModDir)
+	if err != nil {
+		log.Fatal().Msgf("Could not open mod file cache: %v", err)
+	}
 
-		if err := mods.Download(mods.NewGitLabModFetcher(glClient), goModDir); err != nil {
-			log.Fatal().Msgf("Could not download mod files: %v", err)
-		}
+	if err := cache.Refresh(backends, cfg.maxAge, cfg.refresh); err != nil {
+		log.Fatal().Msgf("Could not refresh mod file cache: %v", err)
 	}
 
-	modFiles, err := mods.ReadModFiles(goModDir)
+	modFiles, err := cache.ModFiles()
 	if err != nil {
 		log.Fatal().Msgf("Could not read mod files: %v", err)
 	}
@@ -80,7 +94,7 @@ func main() {
 	depGraph := graph.BuildDependencyGraph(modFiles)
 
 	mux := http.NewServeMux()
-	mux.Handle("/", NewGraphRenderService(depGraph, cfg.goRegistryPrefix))
+	mux.Handle("/", NewGraphRenderService(depGraph, cfg.registryPrefixes))
 
 	// We do this extra work because if port is set to 0 we want to choose a free port automatically
 	listener, err := net.Listen("tcp", cfg.listenAddr)
@@ -97,16 +111,28 @@ func main() {
 
 func configure() *config {
 	var (
-		baseURL    string
-		homeModule string
-		cleanup    bool
-		listenAddr string
+		baseURL         string
+		homeModule      string
+		cleanup         bool
+		listenAddr      string
+		ghOwner         string
+		ghOwnerOrg      bool
+		ghTopics        string
+		skipPrereleases bool
+		refresh         bool
+		maxAge          time.Duration
 	)
 
 	flag.StringVar(&baseURL, "gitlab-base-url", "", "GitLab's API Base URL")
 	flag.StringVar(&homeModule, "mod", "", "Show graph of this module instead of giant overview graph")
-	flag.BoolVar(&cleanup, "cleanup", false, "Clean up the cache directory, enforcing all information to be refetched")
+	flag.BoolVar(&cleanup, "cleanup", false, "Wipe the cache directory entirely, enforcing all information to be refetched")
 	flag.StringVar(&listenAddr, "listen-addr", "localhost:0", "Listen on the given interface and port, set port to 0 to have a free one chosen automatically")
+	flag.StringVar(&ghOwner, "github-owner", "", "GitHub org or user to scan for go.mod files, enables the GitHub backend (requires "+ghTokenEnvVar+")")
+	flag.BoolVar(&ghOwnerOrg, "github-owner-is-org", true, "Whether -github-owner names an organization (true) or a user (false)")
+	flag.StringVar(&ghTopics, "github-topics", "", "Comma-separated list of topics repositories must all carry to be considered, e.g. \"go-module\"")
+	flag.BoolVar(&skipPrereleases, "skip-prereleases", false, "Ignore semver pre-release tags (e.g. v1.2.0-rc.1) when determining a project's latest version")
+	flag.BoolVar(&refresh, "refresh", false, "Re-download every project's go.mod, ignoring both -max-age and an unchanged go.mod blob SHA")
+	flag.DurationVar(&maxAge, "max-age", time.Hour, "Trust a cached project's go.mod without re-checking the remote until this long after it was last fetched")
 
 	flag.Parse()
 
@@ -129,12 +155,41 @@ func configure() *config {
 		log.Fatal().Msgf("GitLab's API token required but the env variable (%q) is set", glTokenEnvVar)
 	}
 
+	registryPrefixes := map[string]string{
+		gitlabBackendName: fmt.Sprintf("%s/", baseURLAsURL.Hostname()),
+	}
+
+	ghToken := os.Getenv(ghTokenEnvVar)
+
+	var topics []string
+
+	if ghTopics != "" {
+		topics = strings.Split(ghTopics, ",")
+	}
+
+	if ghOwner != "" {
+		if ghToken == "" {
+			log.Fatal().Msgf("GitHub owner given but the env variable (%q) carrying the API token is not set", ghTokenEnvVar)
+		}
+
+		registryPrefixes[githubBackendName] = "github.com/"
+	} else {
+		ghToken = "" // the GitHub backend is only wired up once an owner to scan is configured
+	}
+
 	return &config{
 		glToken:          token,
 		glBaseURL:        baseURL,
+		ghToken:          ghToken,
+		ghOwner:          ghOwner,
+		ghOwnerOrg:       ghOwnerOrg,
+		ghTopics:         topics,
+		skipPrereleases:  skipPrereleases,
 		homeModule:       homeModule,
-		goRegistryPrefix: fmt.Sprintf("%s/", baseURLAsURL.Hostname()),
+		registryPrefixes: registryPrefixes,
 		cleanup:          cleanup,
+		refresh:          refresh,
+		maxAge:           maxAge,
 		listenAddr:       listenAddr,
 	}
 }
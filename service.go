@@ -2,9 +2,13 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"html/template"
 	"net/http"
 	"path"
+	"strconv"
+	"strings"
 
 	"github.com/goccy/go-graphviz"
 	"github.com/rs/zerolog/log"
@@ -13,18 +17,32 @@ import (
 )
 
 type GraphRenderService struct {
-	rootGraph        *graph.DependencyGraph
-	goRegistryPrefix string
+	rootGraph *graph.DependencyGraph
+	// registryPrefixes maps an origin (backend name) to the registry prefix used to look up and display modules
+	// fetched from that origin, e.g. "gitlab" -> "gitlab.example.com/" and "github" -> "github.com/".
+	registryPrefixes map[string]string
 }
 
-func NewGraphRenderService(rootGraph *graph.DependencyGraph, goRegistryPrefix string) *GraphRenderService {
+func NewGraphRenderService(rootGraph *graph.DependencyGraph, registryPrefixes map[string]string) *GraphRenderService {
 	return &GraphRenderService{
 		rootGraph:        rootGraph,
-		goRegistryPrefix: goRegistryPrefix,
+		registryPrefixes: registryPrefixes,
 	}
 }
 
 func (g *GraphRenderService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/releases" {
+		g.serveReleases(w, r)
+
+		return
+	}
+
+	if r.URL.Path == "/api/graph" || strings.HasPrefix(r.URL.Path, "/api/graph/") {
+		g.serveAPIGraph(w, r)
+
+		return
+	}
+
 	if r.URL.Path != "/" {
 		http.NotFound(w, r)
 
@@ -43,16 +61,7 @@ func (g *GraphRenderService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	centerModule := g.rootGraph.LookupNode(mod)
-
-	if centerModule == nil {
-		// Try again, this time adding known goRegistryPrefix as prefix.
-		// By this we allow omitting the registry when stating a module.
-		// As this tool focuses on a set of owned modules it is highly unlikely to run into collisions not considering
-		// the registry prefix
-
-		centerModule = g.rootGraph.LookupNode(path.Join(g.goRegistryPrefix, mod))
-	}
+	centerModule := g.lookupModule(mod)
 
 	if centerModule != nil {
 		log.Info().Msgf("Serving graph for module: %s", centerModule.ModuleName)
@@ -65,6 +74,150 @@ func (g *GraphRenderService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	http.Error(w, fmt.Sprintf("%q is not a known module.", mod), http.StatusBadRequest)
 }
 
+// lookupModule looks up name in the root graph, additionally trying each known registry prefix in turn so that
+// callers may omit the registry when naming a module. As this tool focuses on a set of owned modules it is highly
+// unlikely to run into collisions not considering the registry prefix.
+func (g *GraphRenderService) lookupModule(name string) *graph.ModuleNode {
+	if node := g.rootGraph.LookupNode(name); node != nil {
+		return node
+	}
+
+	for _, prefix := range g.registryPrefixes {
+		if node := g.rootGraph.LookupNode(path.Join(prefix, name)); node != nil {
+			return node
+		}
+	}
+
+	return nil
+}
+
+// serveAPIGraph serves /api/graph (the full dependency graph) and /api/graph/{module} (the subgraph around module,
+// whose radius defaults to 1 hop and can be widened via ?depth=N) as JSON.
+func (g *GraphRenderService) serveAPIGraph(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	mod := strings.TrimPrefix(r.URL.Path, "/api/graph/")
+
+	if mod == "" || r.URL.Path == "/api/graph" {
+		if err := json.NewEncoder(w).Encode(g.rootGraph.ToJSON()); err != nil {
+			log.Error().Msgf("Failed to encode graph: %v", err)
+		}
+
+		return
+	}
+
+	centerModule := g.lookupModule(mod)
+	if centerModule == nil {
+		http.Error(w, fmt.Sprintf("%q is not a known module.", mod), http.StatusBadRequest)
+
+		return
+	}
+
+	depth := 1
+
+	if depthParam := r.URL.Query().Get("depth"); depthParam != "" {
+		parsed, err := strconv.Atoi(depthParam)
+		if err != nil || parsed <= 0 {
+			http.Error(w, fmt.Sprintf("%q is not a valid depth, expected a positive integer.", depthParam), http.StatusBadRequest)
+
+			return
+		}
+
+		depth = parsed
+	}
+
+	if err := json.NewEncoder(w).Encode(g.rootGraph.SubgraphFromWithDepth(centerModule, depth).ToJSON()); err != nil {
+		log.Error().Msgf("Failed to encode subgraph: %v", err)
+	}
+}
+
+// releasesTemplate renders the HTML table variant of /releases.
+var releasesTemplate = template.Must(template.New("releases").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Release candidates</title></head>
+<body>
+<h1>Release candidates</h1>
+<table border="1" cellpadding="4" cellspacing="0">
+<tr><th>Module</th><th>Current</th><th>Suggested</th><th>Wave</th><th>Outdated dependencies</th><th>Downstream modules</th></tr>
+{{range .Candidates}}
+<tr>
+<td>{{.ModuleName}}</td>
+<td>{{.CurrentVersion}}</td>
+<td>{{.SuggestedVersion}}</td>
+<td>{{.Wave}}</td>
+<td>{{range .Bumps}}{{.DependencyModule}}: {{.RequiredVersion}} -> {{.LatestVersion}} ({{.Level}})<br>{{end}}</td>
+<td>{{range .Downstream}}{{.}}<br>{{end}}</td>
+</tr>
+{{end}}
+</table>
+{{if .Cycles}}
+<h2>Cycles (each broken at its lowest-version edge to compute the plan above)</h2>
+<ul>
+{{range .Cycles}}<li>{{range .Modules}}{{.}} -> {{end}}(cycle)</li>{{end}}
+</ul>
+{{end}}
+</body>
+</html>
+`))
+
+func (g *GraphRenderService) serveReleases(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Has("svg") || r.URL.Query().Has("png") {
+		g.renderAndReplyReleaseWaves(w, r.URL.Query().Has("png"))
+
+		return
+	}
+
+	candidates, cycles := graph.ReleasePlan(g.rootGraph)
+
+	if r.URL.Query().Has("json") {
+		w.Header().Set("Content-Type", "application/json")
+
+		if err := json.NewEncoder(w).Encode(struct {
+			Candidates []graph.ReleaseCandidate `json:"candidates"`
+			Cycles     []graph.Cycle            `json:"cycles"`
+		}{candidates, cycles}); err != nil {
+			log.Error().Msgf("Failed to encode release plan: %v", err)
+		}
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if err := releasesTemplate.Execute(w, struct {
+		Candidates []graph.ReleaseCandidate
+		Cycles     []graph.Cycle
+	}{candidates, cycles}); err != nil {
+		log.Error().Msgf("Failed to render release plan: %v", err)
+	}
+}
+
+func (g *GraphRenderService) renderAndReplyReleaseWaves(w http.ResponseWriter, asPNG bool) {
+	buffer := bytes.NewBuffer([]byte{})
+
+	format := graphviz.SVG
+
+	if asPNG {
+		format = graphviz.PNG
+	}
+
+	if err := g.rootGraph.RenderReleaseWaves(buffer, g.registryPrefixes, format); err != nil {
+		log.Error().Msgf("Failed to serve request: %v", err)
+
+		http.Error(w, "Failed to render release waves", http.StatusInternalServerError)
+
+		return
+	}
+
+	if asPNG {
+		w.Header().Set("Content-Type", "image/png")
+	} else {
+		w.Header().Set("Content-Type", "image/svg+xml")
+	}
+
+	_, _ = w.Write(buffer.Bytes())
+}
+
 func (g *GraphRenderService) renderAndReply(w http.ResponseWriter, graph *graph.DependencyGraph, asPNG bool) {
 	// We buffer the output in order to ensure we do not end up with an error half-way
 	buffer := bytes.NewBuffer([]byte{})
@@ -75,7 +228,7 @@ func (g *GraphRenderService) renderAndReply(w http.ResponseWriter, graph *graph.
 		format = graphviz.PNG
 	}
 
-	if err := graph.Render(buffer, g.goRegistryPrefix, format); err != nil {
+	if err := graph.Render(buffer, g.registryPrefixes, format); err != nil {
 		log.Error().Msgf("Failed to serve request: %v", err)
 
 		http.Error(w, "Failed to render graph", http.StatusInternalServerError)